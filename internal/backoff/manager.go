@@ -0,0 +1,122 @@
+// Package backoff implements a per-upstream exponential backoff tracker,
+// inspired by Kubernetes client-go's URLBackoff. It lets the proxy layer
+// temporarily skip an upstream that has recently failed instead of
+// hammering it on every subsequent request.
+package backoff
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Config defines the tunable parameters of the backoff algorithm.
+type Config struct {
+	// BaseDelay is the delay applied after the first failure.
+	BaseDelay time.Duration
+	// MaxDelay caps how large the delay can grow to.
+	MaxDelay time.Duration
+	// Multiplier is applied to the current delay on each consecutive failure.
+	Multiplier float64
+}
+
+// entry tracks the backoff state for a single upstream key.
+type entry struct {
+	lastFailureTime     time.Time
+	currentDelay        time.Duration
+	consecutiveFailures int
+}
+
+// Manager tracks per-upstream backoff state keyed by host (or host+path
+// prefix). It is safe for concurrent use.
+type Manager struct {
+	config Config
+	lock   sync.RWMutex
+	states map[string]*entry
+}
+
+// NewManager creates a new backoff Manager with the given configuration.
+func NewManager(config Config) *Manager {
+	return &Manager{
+		config: config,
+		states: make(map[string]*entry),
+	}
+}
+
+// ShouldSkip reports whether the upstream identified by key is still within
+// its backoff window and should be skipped in favor of another candidate.
+func (m *Manager) ShouldSkip(key string) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	e, exists := m.states[key]
+	if !exists {
+		return false
+	}
+	return time.Now().Before(e.lastFailureTime.Add(e.currentDelay))
+}
+
+// RecordFailure registers a transport error or 5xx response from the given
+// upstream, multiplying its current delay by Multiplier up to MaxDelay.
+func (m *Manager) RecordFailure(key string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	e, exists := m.states[key]
+	if !exists {
+		e = &entry{currentDelay: m.config.BaseDelay}
+		m.states[key] = e
+	} else if e.currentDelay == 0 {
+		e.currentDelay = m.config.BaseDelay
+	} else {
+		e.currentDelay = time.Duration(float64(e.currentDelay) * m.config.Multiplier)
+		if e.currentDelay > m.config.MaxDelay {
+			e.currentDelay = m.config.MaxDelay
+		}
+	}
+
+	e.lastFailureTime = time.Now()
+	e.consecutiveFailures++
+}
+
+// RecordSuccess resets the backoff state for the given upstream, e.g. after
+// a successful response has been received from it.
+func (m *Manager) RecordSuccess(key string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	delete(m.states, key)
+}
+
+// Prune evicts entries whose backoff window closed more than idleFor ago, so
+// a long-running instance doesn't accumulate state forever for every
+// distinct upstream key it has ever seen.
+func (m *Manager) Prune(idleFor time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	cutoff := time.Now().Add(-idleFor)
+	for key, e := range m.states {
+		if e.lastFailureTime.Add(e.currentDelay).Before(cutoff) {
+			delete(m.states, key)
+		}
+	}
+}
+
+// RunPruner starts a background goroutine that calls Prune on the given
+// interval until ctx is cancelled. Callers embedding a Manager in a
+// long-running process should start this once at startup.
+func (m *Manager) RunPruner(ctx context.Context, interval, idleFor time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.Prune(idleFor)
+			}
+		}
+	}()
+}
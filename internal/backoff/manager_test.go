@@ -0,0 +1,82 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManagerDoublesDelayUpToMax(t *testing.T) {
+	m := NewManager(Config{BaseDelay: time.Second, MaxDelay: 4 * time.Second, Multiplier: 2})
+
+	m.RecordFailure("up1")
+	if got := m.states["up1"].currentDelay; got != time.Second {
+		t.Fatalf("first failure delay = %v, want %v", got, time.Second)
+	}
+
+	m.RecordFailure("up1")
+	if got := m.states["up1"].currentDelay; got != 2*time.Second {
+		t.Fatalf("second failure delay = %v, want %v", got, 2*time.Second)
+	}
+
+	m.RecordFailure("up1")
+	if got := m.states["up1"].currentDelay; got != 4*time.Second {
+		t.Fatalf("third failure delay = %v, want %v", got, 4*time.Second)
+	}
+
+	m.RecordFailure("up1")
+	if got := m.states["up1"].currentDelay; got != 4*time.Second {
+		t.Fatalf("delay exceeded MaxDelay: got %v, want %v", got, 4*time.Second)
+	}
+}
+
+func TestManagerShouldSkip(t *testing.T) {
+	m := NewManager(Config{BaseDelay: 50 * time.Millisecond, MaxDelay: time.Second, Multiplier: 2})
+
+	if m.ShouldSkip("up1") {
+		t.Fatal("unknown key should not be skipped")
+	}
+
+	m.RecordFailure("up1")
+	if !m.ShouldSkip("up1") {
+		t.Fatal("upstream should be skipped immediately after a failure")
+	}
+
+	time.Sleep(75 * time.Millisecond)
+	if m.ShouldSkip("up1") {
+		t.Fatal("upstream should no longer be skipped once its delay has elapsed")
+	}
+}
+
+func TestManagerRecordSuccessResetsState(t *testing.T) {
+	m := NewManager(Config{BaseDelay: time.Second, MaxDelay: 4 * time.Second, Multiplier: 2})
+
+	m.RecordFailure("up1")
+	if !m.ShouldSkip("up1") {
+		t.Fatal("expected upstream to be in backoff after a failure")
+	}
+
+	m.RecordSuccess("up1")
+	if m.ShouldSkip("up1") {
+		t.Fatal("expected RecordSuccess to clear backoff state")
+	}
+	if _, exists := m.states["up1"]; exists {
+		t.Fatal("expected RecordSuccess to delete the entry entirely")
+	}
+}
+
+func TestManagerPruneEvictsExpiredEntries(t *testing.T) {
+	m := NewManager(Config{BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 2})
+
+	m.RecordFailure("stale")
+	time.Sleep(5 * time.Millisecond)
+	m.RecordFailure("fresh")
+
+	m.Prune(2 * time.Millisecond)
+
+	if _, exists := m.states["stale"]; exists {
+		t.Fatal("expected Prune to evict the stale entry")
+	}
+	if _, exists := m.states["fresh"]; !exists {
+		t.Fatal("expected Prune to keep the recently-failed entry")
+	}
+}
@@ -1,8 +1,8 @@
 package httpclient
 
 import (
-	"fmt"
 	"crypto/tls"
+	"fmt"
 	"net"
 	"net/http"
 	"net/url"
@@ -89,10 +89,10 @@ func (m *HTTPClientManager) GetClient(config *Config) *http.Client {
 
 	// Configure TLS settings for proxy if needed
 	if config.ProxyTLSSkipVerify {
-	    transport.TLSClientConfig = &tls.Config{
-	        InsecureSkipVerify: true,
-	    }
-	    logrus.Warnf("Proxy TLS certificate verification is disabled - this is not recommended for production use")
+		transport.TLSClientConfig = &tls.Config{
+			InsecureSkipVerify: true,
+		}
+		logrus.Warnf("Proxy TLS certificate verification is disabled - this is not recommended for production use")
 	}
 
 	// Set http proxy.
@@ -0,0 +1,86 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gpt-load/internal/models"
+)
+
+// cloudLogRecord is the structured record shape expected by the generic
+// cloud log-service API: a topic ID plus a batch of JSON records.
+type cloudLogRecord struct {
+	Topic   string              `json:"topic"`
+	Records []models.RequestLog `json:"records"`
+}
+
+// CloudLogsSink ships request logs to a generic structured cloud log
+// service, modeled after hosted log-service APIs that accept a topic ID
+// and a batch of JSON records with retry.
+type CloudLogsSink struct {
+	endpoint  string
+	authToken string
+	topic     string
+	client    *http.Client
+}
+
+// NewCloudLogsSink creates a CloudLogsSink. cfg.Topic identifies the
+// destination topic/index on the remote service.
+func NewCloudLogsSink(cfg Config) *CloudLogsSink {
+	return &CloudLogsSink{
+		endpoint:  cfg.Endpoint,
+		authToken: cfg.AuthToken,
+		topic:     cfg.Topic,
+		client:    &http.Client{},
+	}
+}
+
+// Write submits the batch as a single record set, retrying once if the
+// first attempt fails for any reason (transport error or non-2xx status)
+// before surfacing the error to the caller (which buffers to the on-disk
+// spool).
+func (s *CloudLogsSink) Write(ctx context.Context, logs []models.RequestLog) error {
+	payload, err := json.Marshal(cloudLogRecord{Topic: s.topic, Records: logs})
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if lastErr = s.post(ctx, payload); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+func (s *CloudLogsSink) post(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("cloud-logs sink: upstream returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close releases the HTTP client's idle connections.
+func (s *CloudLogsSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,42 @@
+package logsink
+
+import (
+	"context"
+	"errors"
+
+	"gpt-load/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// errNoDB is returned by Write when the sink was constructed without a
+// database connection, so the caller's existing spool-on-failure path
+// buffers the logs instead of them silently vanishing.
+var errNoDB = errors.New("logsink: db sink has no database connection")
+
+// DBSink persists request logs to the database, preserving the behavior
+// that existed before pluggable sinks were introduced.
+type DBSink struct {
+	db *gorm.DB
+}
+
+// NewDBSink creates a DBSink backed by db.
+func NewDBSink(db *gorm.DB) *DBSink {
+	return &DBSink{db: db}
+}
+
+// Write inserts the given logs in a single batch.
+func (s *DBSink) Write(ctx context.Context, logs []models.RequestLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	if s.db == nil {
+		return errNoDB
+	}
+	return s.db.WithContext(ctx).Create(&logs).Error
+}
+
+// Close is a no-op; the underlying *gorm.DB is owned elsewhere.
+func (s *DBSink) Close() error {
+	return nil
+}
@@ -0,0 +1,23 @@
+package logsink
+
+import (
+	"context"
+	"testing"
+
+	"gpt-load/internal/models"
+)
+
+func TestDBSinkWriteWithoutDBReturnsError(t *testing.T) {
+	s := NewDBSink(nil)
+	err := s.Write(context.Background(), []models.RequestLog{{GroupName: "g1"}})
+	if err == nil {
+		t.Fatal("expected Write to return an error when no *gorm.DB is configured, got nil")
+	}
+}
+
+func TestDBSinkWriteWithoutDBIgnoresEmptyBatch(t *testing.T) {
+	s := NewDBSink(nil)
+	if err := s.Write(context.Background(), nil); err != nil {
+		t.Fatalf("expected Write of an empty batch to succeed even without a DB, got %v", err)
+	}
+}
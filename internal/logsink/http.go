@@ -0,0 +1,62 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gpt-load/internal/models"
+)
+
+// HTTPJSONSink delivers request logs as a batched JSON POST to a
+// user-configured URL, authenticated with a bearer token when provided.
+type HTTPJSONSink struct {
+	endpoint  string
+	authToken string
+	client    *http.Client
+}
+
+// NewHTTPJSONSink creates an HTTPJSONSink targeting cfg.Endpoint.
+func NewHTTPJSONSink(cfg Config) *HTTPJSONSink {
+	return &HTTPJSONSink{
+		endpoint:  cfg.Endpoint,
+		authToken: cfg.AuthToken,
+		client:    &http.Client{},
+	}
+}
+
+// Write POSTs the batch of logs as a single JSON array body.
+func (s *HTTPJSONSink) Write(ctx context.Context, logs []models.RequestLog) error {
+	payload, err := json.Marshal(logs)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("http-json sink: upstream returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close releases the HTTP client's idle connections.
+func (s *HTTPJSONSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
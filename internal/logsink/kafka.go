@@ -0,0 +1,48 @@
+package logsink
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"gpt-load/internal/models"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaSink publishes request logs as JSON-encoded messages to a Kafka
+// topic, one message per log entry.
+type KafkaSink struct {
+	writer *kafka.Writer
+}
+
+// NewKafkaSink creates a KafkaSink. cfg.Endpoint is a comma-separated list
+// of broker addresses and cfg.Topic is the destination topic.
+func NewKafkaSink(cfg Config) (*KafkaSink, error) {
+	brokers := strings.Split(cfg.Endpoint, ",")
+	return &KafkaSink{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(brokers...),
+			Topic:    cfg.Topic,
+			Balancer: &kafka.LeastBytes{},
+		},
+	}, nil
+}
+
+// Write publishes each log entry as an individual Kafka message.
+func (s *KafkaSink) Write(ctx context.Context, logs []models.RequestLog) error {
+	messages := make([]kafka.Message, 0, len(logs))
+	for _, log := range logs {
+		payload, err := json.Marshal(log)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, kafka.Message{Key: []byte(log.ID), Value: payload})
+	}
+	return s.writer.WriteMessages(ctx, messages...)
+}
+
+// Close flushes and closes the underlying Kafka writer.
+func (s *KafkaSink) Close() error {
+	return s.writer.Close()
+}
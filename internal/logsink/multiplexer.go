@@ -0,0 +1,196 @@
+package logsink
+
+import (
+	"context"
+	"sync"
+
+	"gpt-load/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// bufferedSink wraps a Sink with its own buffered channel and goroutine so
+// that one slow external sink cannot block writes to the others (including
+// the database sink).
+type bufferedSink struct {
+	sink  Sink
+	queue chan []models.RequestLog
+	spool *Spool
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+const sinkQueueDepth = 16
+
+func newBufferedSink(sink Sink, spoolPath string, spoolMaxBytes int64) *bufferedSink {
+	b := &bufferedSink{
+		sink:  sink,
+		queue: make(chan []models.RequestLog, sinkQueueDepth),
+		done:  make(chan struct{}),
+	}
+	if spoolPath != "" {
+		b.spool = NewSpool(spoolPath, spoolMaxBytes)
+	}
+
+	b.wg.Add(1)
+	go b.loop()
+	return b
+}
+
+func (b *bufferedSink) loop() {
+	defer b.wg.Done()
+
+	if b.spool != nil {
+		b.drainSpool()
+	}
+
+	for {
+		select {
+		case logs := <-b.queue:
+			b.deliver(logs)
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *bufferedSink) deliver(logs []models.RequestLog) {
+	if err := b.sink.Write(context.Background(), logs); err != nil {
+		logrus.Errorf("logsink: failed to write %d logs, buffering to spool: %v", len(logs), err)
+		if b.spool != nil {
+			if spoolErr := b.spool.Append(logs); spoolErr != nil {
+				logrus.Errorf("logsink: failed to spool logs: %v", spoolErr)
+			}
+		}
+	}
+}
+
+func (b *bufferedSink) drainSpool() {
+	pending, err := b.spool.Drain()
+	if err != nil {
+		logrus.Errorf("logsink: failed to read spool: %v", err)
+		return
+	}
+	for _, logs := range pending {
+		b.deliver(logs)
+	}
+}
+
+// Enqueue submits a batch of logs for asynchronous delivery. It never
+// blocks the caller; if the queue is full the batch is spooled directly, or
+// dropped when the sink has no spool configured.
+func (b *bufferedSink) Enqueue(logs []models.RequestLog) {
+	select {
+	case b.queue <- logs:
+	default:
+		logrus.Warnf("logsink: sink queue full, spooling %d logs", len(logs))
+		if b.spool != nil {
+			if err := b.spool.Append(logs); err != nil {
+				logrus.Errorf("logsink: failed to spool logs: %v", err)
+			}
+		}
+	}
+}
+
+// SpoolDepth returns the number of batches currently buffered on disk,
+// exposed via metrics so operators can see when an external sink is down.
+func (b *bufferedSink) SpoolDepth() int {
+	if b.spool == nil {
+		return 0
+	}
+	return b.spool.Depth()
+}
+
+func (b *bufferedSink) stop() {
+	close(b.done)
+	b.wg.Wait()
+	_ = b.sink.Close()
+}
+
+// Multiplexer fans a single stream of request logs out to multiple
+// composable sinks, each delivered independently.
+type Multiplexer struct {
+	sinks []*bufferedSink
+}
+
+// NewMultiplexer builds a Multiplexer from the given sink configurations,
+// constructing the built-in implementation for each Type. db is wired into
+// any TypeDB (or default) sink; it may be nil if no sink in configs needs
+// database access.
+func NewMultiplexer(db *gorm.DB, configs []Config) (*Multiplexer, error) {
+	m := &Multiplexer{}
+	for _, cfg := range configs {
+		sink, err := newSink(db, cfg)
+		if err != nil {
+			return nil, err
+		}
+		m.sinks = append(m.sinks, newBufferedSink(sink, cfg.SpoolPath, cfg.SpoolMaxBytes))
+	}
+	return m, nil
+}
+
+// Write enqueues logs for delivery to every configured sink.
+func (m *Multiplexer) Write(logs []models.RequestLog) {
+	for _, s := range m.sinks {
+		s.Enqueue(logs)
+	}
+}
+
+// SpoolDepths returns the on-disk spool depth of each sink keyed by its
+// Type, for metrics. Sinks sharing a Type share one entry, holding the depth
+// of the last such sink.
+func (m *Multiplexer) SpoolDepths() map[Type]int {
+	depths := make(map[Type]int, len(m.sinks))
+	for _, s := range m.sinks {
+		depths[typeOf(s.sink)] = s.SpoolDepth()
+	}
+	return depths
+}
+
+// Close stops every sink goroutine and releases its resources.
+func (m *Multiplexer) Close() {
+	for _, s := range m.sinks {
+		s.stop()
+	}
+}
+
+func newSink(db *gorm.DB, cfg Config) (Sink, error) {
+	switch cfg.Type {
+	case TypeDB, "":
+		return NewDBSink(db), nil
+	case TypeKafka:
+		return NewKafkaSink(cfg)
+	case TypeHTTPJSON:
+		return NewHTTPJSONSink(cfg), nil
+	case TypeCloudLogs:
+		return NewCloudLogsSink(cfg), nil
+	default:
+		return nil, &UnknownSinkTypeError{Type: cfg.Type}
+	}
+}
+
+func typeOf(s Sink) Type {
+	switch s.(type) {
+	case *DBSink:
+		return TypeDB
+	case *KafkaSink:
+		return TypeKafka
+	case *HTTPJSONSink:
+		return TypeHTTPJSON
+	case *CloudLogsSink:
+		return TypeCloudLogs
+	default:
+		return ""
+	}
+}
+
+// UnknownSinkTypeError is returned when a sink configuration names a Type
+// that has no built-in implementation.
+type UnknownSinkTypeError struct {
+	Type Type
+}
+
+func (e *UnknownSinkTypeError) Error() string {
+	return "logsink: unknown sink type " + string(e.Type)
+}
@@ -0,0 +1,76 @@
+package logsink
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gpt-load/internal/models"
+)
+
+var errFlaky = errors.New("flaky sink: simulated failure")
+
+// flakySink fails every Write until calls exceeds failUntil, then records
+// every batch it receives.
+type flakySink struct {
+	mu        sync.Mutex
+	failUntil int
+	calls     int
+	received  [][]models.RequestLog
+}
+
+func (f *flakySink) Write(_ context.Context, logs []models.RequestLog) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failUntil {
+		return errFlaky
+	}
+	f.received = append(f.received, logs)
+	return nil
+}
+
+func (f *flakySink) Close() error { return nil }
+
+func (f *flakySink) receivedCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.received)
+}
+
+func TestBufferedSinkSpoolsOnFailureAndDrainsOnRestart(t *testing.T) {
+	spoolPath := filepath.Join(t.TempDir(), "spool.jsonl")
+
+	down := &flakySink{failUntil: 1000} // always fails
+	b := newBufferedSink(down, spoolPath, 0)
+	b.Enqueue([]models.RequestLog{{GroupName: "g1"}})
+
+	deadline := time.Now().Add(time.Second)
+	for b.SpoolDepth() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := b.SpoolDepth(); got != 1 {
+		t.Fatalf("SpoolDepth after a failed write = %d, want 1", got)
+	}
+	b.stop()
+
+	// Simulate the sink recovering: a new bufferedSink over the same spool
+	// path should drain and deliver the previously-failed batch.
+	up := &flakySink{}
+	b2 := newBufferedSink(up, spoolPath, 0)
+	defer b2.stop()
+
+	deadline = time.Now().Add(time.Second)
+	for up.receivedCount() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := up.receivedCount(); got != 1 {
+		t.Fatalf("receivedCount after recovery = %d, want 1", got)
+	}
+	if got := b2.SpoolDepth(); got != 0 {
+		t.Fatalf("SpoolDepth after drain = %d, want 0", got)
+	}
+}
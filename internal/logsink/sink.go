@@ -0,0 +1,47 @@
+// Package logsink fans out models.RequestLog entries to one or more
+// external log platforms in addition to (or instead of) the database,
+// so operators can pipe request logs into Kafka, a webhook, or a cloud log
+// service without forking the log writer.
+package logsink
+
+import (
+	"context"
+
+	"gpt-load/internal/models"
+)
+
+// Sink persists a batch of request logs to a single destination. The
+// Multiplexer wraps each Sink in its own buffered queue and goroutine, so a
+// slow destination cannot block the others.
+type Sink interface {
+	// Write delivers a batch of request logs. It is called with the same
+	// cadence as the existing RequestLogWriteIntervalMinutes flush.
+	Write(ctx context.Context, logs []models.RequestLog) error
+	// Close releases any resources held by the sink (connections, files).
+	Close() error
+}
+
+// Type identifies a built-in Sink implementation.
+type Type string
+
+const (
+	TypeDB        Type = "db"
+	TypeKafka     Type = "kafka"
+	TypeHTTPJSON  Type = "http-json"
+	TypeCloudLogs Type = "cloud-logs"
+)
+
+// Config configures a single sink instance. Which fields are required
+// depends on Type: Kafka uses Endpoint as the broker list and Topic as the
+// topic name, HTTPJSON uses Endpoint and AuthToken as a bearer token,
+// CloudLogs uses Endpoint, AuthToken and Topic (the log topic/index ID).
+type Config struct {
+	Type          Type
+	Endpoint      string
+	AuthToken     string
+	Topic         string
+	BatchSize     int
+	FlushInterval int // seconds
+	SpoolPath     string
+	SpoolMaxBytes int64 // caps the on-disk spool file; 0 uses DefaultSpoolMaxBytes
+}
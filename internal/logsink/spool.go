@@ -0,0 +1,181 @@
+package logsink
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"gpt-load/internal/models"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSpoolMaxBytes bounds a sink's on-disk spool file when no explicit
+// Config.SpoolMaxBytes is set, so a sink that stays down indefinitely can't
+// grow the spool without limit.
+const DefaultSpoolMaxBytes = 64 * 1024 * 1024 // 64MiB
+
+// Spool is a bounded on-disk buffer for log batches that could not be
+// delivered to a sink, drained once the sink recovers. Batches are appended
+// as line-delimited JSON so a crash mid-append only loses the partial last
+// line. Once the file reaches maxBytes, the oldest batches are dropped to
+// make room for new ones by rewriting the file through a temporary file
+// and a rename.
+type Spool struct {
+	path     string
+	maxBytes int64
+	lock     sync.Mutex
+}
+
+// NewSpool creates a Spool backed by the file at path, capped at maxBytes.
+// maxBytes <= 0 uses DefaultSpoolMaxBytes.
+func NewSpool(path string, maxBytes int64) *Spool {
+	if maxBytes <= 0 {
+		maxBytes = DefaultSpoolMaxBytes
+	}
+	return &Spool{path: path, maxBytes: maxBytes}
+}
+
+// Append writes a batch of logs as a new line in the spool file, dropping
+// the oldest buffered batches first if needed to stay within maxBytes. A
+// batch whose own line exceeds maxBytes is still written, after every older
+// batch has been dropped.
+func (s *Spool) Append(logs []models.RequestLog) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	payload, err := json.Marshal(logs)
+	if err != nil {
+		return err
+	}
+	line := append(payload, '\n')
+
+	if err := s.makeRoom(int64(len(line))); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(line)
+	return err
+}
+
+// makeRoom drops the oldest spooled batches, if any, until appending an
+// entry of size incoming would fit within maxBytes.
+func (s *Spool) makeRoom(incoming int64) error {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size()+incoming <= s.maxBytes {
+		return nil
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	var lines [][]byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	scanErr := scanner.Err()
+	f.Close()
+	if scanErr != nil {
+		return scanErr
+	}
+
+	size := incoming
+	for _, l := range lines {
+		size += int64(len(l)) + 1
+	}
+	dropped := 0
+	for size > s.maxBytes && len(lines) > 0 {
+		size -= int64(len(lines[0])) + 1
+		lines = lines[1:]
+		dropped++
+	}
+	if dropped > 0 {
+		logrus.Warnf("logsink: spool %s at capacity, dropped %d oldest batch(es)", s.path, dropped)
+	}
+
+	tmp := s.path + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	for _, l := range lines {
+		if _, err := out.Write(append(l, '\n')); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Drain reads and removes every buffered batch from the spool file.
+func (s *Spool) Drain() ([][]models.RequestLog, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var batches [][]models.RequestLog
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	for scanner.Scan() {
+		var logs []models.RequestLog
+		if err := json.Unmarshal(scanner.Bytes(), &logs); err != nil {
+			continue
+		}
+		batches = append(batches, logs)
+	}
+	if err := scanner.Err(); err != nil {
+		return batches, err
+	}
+
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		return batches, err
+	}
+	return batches, nil
+}
+
+// Depth returns the number of buffered batches currently on disk.
+func (s *Spool) Depth() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	count := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+	for scanner.Scan() {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,57 @@
+package logsink
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gpt-load/internal/models"
+)
+
+func TestSpoolAppendAndDrain(t *testing.T) {
+	dir := t.TempDir()
+	s := NewSpool(filepath.Join(dir, "spool.jsonl"), 0)
+
+	batch := []models.RequestLog{{GroupName: "g1"}}
+	if err := s.Append(batch); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+	if got := s.Depth(); got != 1 {
+		t.Fatalf("Depth = %d, want 1", got)
+	}
+
+	drained, err := s.Drain()
+	if err != nil {
+		t.Fatalf("Drain returned error: %v", err)
+	}
+	if len(drained) != 1 || len(drained[0]) != 1 || drained[0][0].GroupName != "g1" {
+		t.Fatalf("Drain = %+v, want one batch with GroupName g1", drained)
+	}
+	if got := s.Depth(); got != 0 {
+		t.Fatalf("Depth after Drain = %d, want 0", got)
+	}
+}
+
+func TestSpoolIsBoundedByMaxBytes(t *testing.T) {
+	dir := t.TempDir()
+
+	batch := []models.RequestLog{{GroupName: "some-fairly-long-group-name-to-take-up-space"}}
+
+	// Check a single batch appends cleanly, then cap the spool under test
+	// at 1 byte, smaller than any batch line, so every Append must evict
+	// all older batches and at most one remains.
+	probe := NewSpool(filepath.Join(dir, "probe.jsonl"), 0)
+	if err := probe.Append(batch); err != nil {
+		t.Fatalf("Append returned error: %v", err)
+	}
+
+	s := NewSpool(filepath.Join(dir, "spool.jsonl"), 1) // force makeRoom to trim aggressively
+	for i := 0; i < 10; i++ {
+		if err := s.Append(batch); err != nil {
+			t.Fatalf("Append %d returned error: %v", i, err)
+		}
+	}
+
+	if got := s.Depth(); got > 1 {
+		t.Fatalf("Depth = %d, want spool capped near its byte limit (<=1 batch)", got)
+	}
+}
@@ -25,20 +25,27 @@ type SystemSetting struct {
 
 // GroupConfig 存储特定于分组的配置
 type GroupConfig struct {
-	RequestTimeout               *int    `json:"request_timeout,omitempty"`
-	IdleConnTimeout              *int    `json:"idle_conn_timeout,omitempty"`
-	ConnectTimeout               *int    `json:"connect_timeout,omitempty"`
-	MaxIdleConns                 *int    `json:"max_idle_conns,omitempty"`
-	MaxIdleConnsPerHost          *int    `json:"max_idle_conns_per_host,omitempty"`
-	ResponseHeaderTimeout        *int    `json:"response_header_timeout,omitempty"`
-	ProxyURL                     *string `json:"proxy_url,omitempty"`
-	ProxyTLSSkipVerify           *bool   `json:"proxy_tls_skip_verify,omitempty"`
-	MaxRetries                   *int    `json:"max_retries,omitempty"`
-	BlacklistThreshold           *int    `json:"blacklist_threshold,omitempty"`
-	KeyValidationIntervalMinutes *int    `json:"key_validation_interval_minutes,omitempty"`
-	KeyValidationConcurrency     *int    `json:"key_validation_concurrency,omitempty"`
-	KeyValidationTimeoutSeconds  *int    `json:"key_validation_timeout_seconds,omitempty"`
-	EnableRequestBodyLogging     *bool   `json:"enable_request_body_logging,omitempty"`
+	RequestTimeout               *int     `json:"request_timeout,omitempty"`
+	IdleConnTimeout              *int     `json:"idle_conn_timeout,omitempty"`
+	ConnectTimeout               *int     `json:"connect_timeout,omitempty"`
+	MaxIdleConns                 *int     `json:"max_idle_conns,omitempty"`
+	MaxIdleConnsPerHost          *int     `json:"max_idle_conns_per_host,omitempty"`
+	ResponseHeaderTimeout        *int     `json:"response_header_timeout,omitempty"`
+	ProxyURL                     *string  `json:"proxy_url,omitempty"`
+	ProxyTLSSkipVerify           *bool    `json:"proxy_tls_skip_verify,omitempty"`
+	MaxRetries                   *int     `json:"max_retries,omitempty"`
+	BlacklistThreshold           *int     `json:"blacklist_threshold,omitempty"`
+	KeyValidationIntervalMinutes *int     `json:"key_validation_interval_minutes,omitempty"`
+	KeyValidationConcurrency     *int     `json:"key_validation_concurrency,omitempty"`
+	KeyValidationTimeoutSeconds  *int     `json:"key_validation_timeout_seconds,omitempty"`
+	EnableRequestBodyLogging     *bool    `json:"enable_request_body_logging,omitempty"`
+	UpstreamBackoffBaseSeconds   *int     `json:"upstream_backoff_base_seconds,omitempty"`
+	UpstreamBackoffMaxSeconds    *int     `json:"upstream_backoff_max_seconds,omitempty"`
+	UpstreamBackoffMultiplier    *float64 `json:"upstream_backoff_multiplier,omitempty"`
+	UpstreamLoadBalanceStrategy  *string  `json:"upstream_load_balance_strategy,omitempty"`
+	RequestsPerMinutePerKey      *int     `json:"requests_per_minute_per_key,omitempty"`
+	RequestsPerMinutePerGroup    *int     `json:"requests_per_minute_per_group,omitempty"`
+	TokensPerMinutePerKey        *int     `json:"tokens_per_minute_per_key,omitempty"`
 }
 
 // HeaderRule defines a single rule for header manipulation.
@@ -50,25 +57,29 @@ type HeaderRule struct {
 
 // Group 对应 groups 表
 type Group struct {
-	ID                 uint                 `gorm:"primaryKey;autoIncrement" json:"id"`
-	EffectiveConfig    types.SystemSettings `gorm:"-" json:"effective_config,omitempty"`
-	Name               string               `gorm:"type:varchar(255);not null;unique" json:"name"`
-	Endpoint           string               `gorm:"-" json:"endpoint"`
-	DisplayName        string               `gorm:"type:varchar(255)" json:"display_name"`
-	ProxyKeys          string               `gorm:"type:text" json:"proxy_keys"`
-	Description        string               `gorm:"type:varchar(512)" json:"description"`
-	Upstreams          datatypes.JSON       `gorm:"type:json;not null" json:"upstreams"`
-	ValidationEndpoint string               `gorm:"type:varchar(255)" json:"validation_endpoint"`
-	ChannelType        string               `gorm:"type:varchar(50);not null" json:"channel_type"`
-	Sort               int                  `gorm:"default:0" json:"sort"`
-	TestModel          string               `gorm:"type:varchar(255);not null" json:"test_model"`
-	ParamOverrides     datatypes.JSONMap    `gorm:"type:json" json:"param_overrides"`
-	Config             datatypes.JSONMap    `gorm:"type:json" json:"config"`
-	HeaderRules        datatypes.JSON       `gorm:"type:json" json:"header_rules"`
-	APIKeys            []APIKey             `gorm:"foreignKey:GroupID" json:"api_keys"`
-	LastValidatedAt    *time.Time           `json:"last_validated_at"`
-	CreatedAt          time.Time            `json:"created_at"`
-	UpdatedAt          time.Time            `json:"updated_at"`
+	ID              uint                 `gorm:"primaryKey;autoIncrement" json:"id"`
+	EffectiveConfig types.SystemSettings `gorm:"-" json:"effective_config,omitempty"`
+	Name            string               `gorm:"type:varchar(255);not null;unique" json:"name"`
+	Endpoint        string               `gorm:"-" json:"endpoint"`
+	DisplayName     string               `gorm:"type:varchar(255)" json:"display_name"`
+	ProxyKeys       string               `gorm:"type:text" json:"proxy_keys"`
+	Description     string               `gorm:"type:varchar(512)" json:"description"`
+	// Upstreams 每项可以是纯字符串 URL（历史格式），也可以是包含 weight/max_concurrent/health_path
+	// 等字段的完整对象，由 internal/upstream.ParseUpstreams 负责解析两种格式。
+	Upstreams          datatypes.JSON    `gorm:"type:json;not null" json:"upstreams"`
+	ValidationEndpoint string            `gorm:"type:varchar(255)" json:"validation_endpoint"`
+	ChannelType        string            `gorm:"type:varchar(50);not null" json:"channel_type"`
+	Sort               int               `gorm:"default:0" json:"sort"`
+	TestModel          string            `gorm:"type:varchar(255);not null" json:"test_model"`
+	ParamOverrides     datatypes.JSONMap `gorm:"type:json" json:"param_overrides"`
+	Config             datatypes.JSONMap `gorm:"type:json" json:"config"`
+	HeaderRules        datatypes.JSON    `gorm:"type:json" json:"header_rules"`
+	RequestScript      string            `gorm:"type:text" json:"request_script"`
+	ResponseScript     string            `gorm:"type:text" json:"response_script"`
+	APIKeys            []APIKey          `gorm:"foreignKey:GroupID" json:"api_keys"`
+	LastValidatedAt    *time.Time        `json:"last_validated_at"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
 
 	// For cache
 	ProxyKeysMap   map[string]struct{} `gorm:"-" json:"-"`
@@ -90,8 +101,10 @@ type APIKey struct {
 
 // RequestType 请求类型常量
 const (
-	RequestTypeRetry = "retry"
-	RequestTypeFinal = "final"
+	RequestTypeRetry          = "retry"
+	RequestTypeFinal          = "final"
+	RequestTypeSkippedBackoff = "skipped_backoff"
+	RequestTypeRateLimited    = "rate_limited"
 )
 
 // RequestLog 对应 request_logs 表
@@ -0,0 +1,293 @@
+// Package plugins lets operators attach JavaScript scripts to a Group for
+// programmatic request/response mutation, going beyond what the static
+// HeaderRule/ParamOverrides configuration can express. Scripts are compiled
+// once per group config version and the *goja.Runtime VMs that execute them
+// are pooled and reused across requests, so neither parsing nor VM setup
+// happens on every call.
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// Config controls how scripts are executed.
+type Config struct {
+	// Timeout bounds how long a single script execution may run before it
+	// is aborted.
+	Timeout time.Duration
+}
+
+// maxScriptCallStackSize caps how deep a script's call stack (including
+// recursion) may grow. goja has no byte-level heap limiter, so this is the
+// memory ceiling we can actually enforce: it bounds the dominant way an
+// untrusted script can run the VM's memory usage away from us.
+const maxScriptCallStackSize = 256
+
+// RejectError is returned by RunRequestScript/RunResponseScript when the
+// script called ctx.reject(status, message) to terminate the request early.
+type RejectError struct {
+	Status  int
+	Message string
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf("rejected by plugin script: %d %s", e.Status, e.Message)
+}
+
+// RequestContext is the mutable request state exposed to a RequestScript.
+type RequestContext struct {
+	Headers  map[string]string
+	Body     []byte
+	Path     string
+	Key      string
+	Upstream string
+}
+
+// ResponseContext is the mutable response state exposed to a ResponseScript.
+type ResponseContext struct {
+	Headers    map[string]string
+	Body       []byte
+	StatusCode int
+}
+
+// compiledEntry caches a parsed program alongside the group config version
+// it was compiled for, so a group update invalidates it without a restart.
+type compiledEntry struct {
+	version int64
+	program *goja.Program
+}
+
+// Runtime compiles and runs RequestScript/ResponseScript for groups, caching
+// compiled programs keyed by group ID and reusing *goja.Runtime VMs across
+// executions via vmPool.
+type Runtime struct {
+	config Config
+
+	lock     sync.RWMutex
+	request  map[uint]*compiledEntry
+	response map[uint]*compiledEntry
+	vmPool   sync.Pool
+}
+
+// NewRuntime creates a plugin Runtime with the given configuration.
+func NewRuntime(config Config) *Runtime {
+	r := &Runtime{
+		config:   config,
+		request:  make(map[uint]*compiledEntry),
+		response: make(map[uint]*compiledEntry),
+	}
+	r.vmPool.New = func() any {
+		vm := goja.New()
+		vm.SetMaxCallStackSize(maxScriptCallStackSize)
+		return vm
+	}
+	return r
+}
+
+// InvalidateGroup drops any cached program for groupID, forcing a
+// recompile on its next execution. Passing a new version to
+// RunRequestScript/RunResponseScript also forces a recompile.
+func (r *Runtime) InvalidateGroup(groupID uint) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	delete(r.request, groupID)
+	delete(r.response, groupID)
+}
+
+// RunRequestScript executes the group's RequestScript against ctx, applying
+// any mutations the script makes in place. version should change whenever
+// the script source changes (e.g. the group's UpdatedAt), invalidating the
+// compiled cache automatically.
+func (r *Runtime) RunRequestScript(groupID uint, version int64, source string, ctx *RequestContext) error {
+	program, err := r.getProgram(groupID, version, source, r.request)
+	if err != nil {
+		return err
+	}
+	return r.run(program, func(vm *goja.Runtime) {
+		bindRequestContext(vm, ctx)
+	})
+}
+
+// RunResponseScript executes the group's ResponseScript against ctx.
+func (r *Runtime) RunResponseScript(groupID uint, version int64, source string, ctx *ResponseContext) error {
+	program, err := r.getProgram(groupID, version, source, r.response)
+	if err != nil {
+		return err
+	}
+	return r.run(program, func(vm *goja.Runtime) {
+		bindResponseContext(vm, ctx)
+	})
+}
+
+func (r *Runtime) getProgram(groupID uint, version int64, source string, cache map[uint]*compiledEntry) (*goja.Program, error) {
+	r.lock.RLock()
+	entry, exists := cache[groupID]
+	r.lock.RUnlock()
+	if exists && entry.version == version {
+		return entry.program, nil
+	}
+
+	program, err := goja.Compile(fmt.Sprintf("group-%d", groupID), source, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile plugin script: %w", err)
+	}
+
+	r.lock.Lock()
+	cache[groupID] = &compiledEntry{version: version, program: program}
+	r.lock.Unlock()
+
+	return program, nil
+}
+
+// run executes program in a pooled goja.Runtime, enforcing the configured
+// execution timeout via an interrupt.
+func (r *Runtime) run(program *goja.Program, bind func(vm *goja.Runtime)) (err error) {
+	vm := r.vmPool.Get().(*goja.Runtime)
+	vm.ClearInterrupt()
+	defer r.vmPool.Put(vm)
+
+	bind(vm)
+
+	// guard stops a timer callback that fires after this execution has
+	// already returned (and the vm has gone back to the pool, possibly to a
+	// different request) from interrupting whoever is using it next.
+	guard := &execGuard{}
+	timer := time.AfterFunc(r.config.Timeout, func() {
+		guard.interruptIfLive(vm)
+	})
+	defer timer.Stop()
+	defer guard.finish()
+
+	defer func() {
+		if r := recover(); r != nil {
+			if rej, ok := r.(*RejectError); ok {
+				err = rej
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	_, runErr := vm.RunProgram(program)
+	if runErr != nil {
+		return fmt.Errorf("plugin script execution failed: %w", runErr)
+	}
+	return nil
+}
+
+// execGuard serializes a single execution's "is this timer callback still
+// allowed to interrupt my vm" check against the execution marking itself
+// finished, so a timer that fires just after run() returns can never reach a
+// vm that has since been handed to a different execution.
+type execGuard struct {
+	lock     sync.Mutex
+	finished bool
+}
+
+func (g *execGuard) interruptIfLive(vm *goja.Runtime) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if !g.finished {
+		vm.Interrupt("plugin script execution timed out")
+	}
+}
+
+func (g *execGuard) finish() {
+	g.lock.Lock()
+	g.finished = true
+	g.lock.Unlock()
+}
+
+func bindRequestContext(vm *goja.Runtime, ctx *RequestContext) {
+	obj := vm.NewObject()
+
+	_ = obj.Set("headers", ctx.Headers)
+	_ = obj.Set("path", ctx.Path)
+	_ = obj.Set("key", ctx.Key)
+	_ = obj.Set("upstream", ctx.Upstream)
+
+	var body any
+	if len(ctx.Body) > 0 {
+		if err := json.Unmarshal(ctx.Body, &body); err == nil {
+			_ = obj.Set("body", body)
+		}
+	}
+
+	root := vm.NewObject()
+	_ = root.Set("request", obj)
+	_ = root.Set("key", ctx.Key)
+	_ = root.Set("upstream", ctx.Upstream)
+
+	_ = root.Set("setHeader", func(key, value string) {
+		ctx.Headers[key] = value
+	})
+	_ = root.Set("removeHeader", func(key string) {
+		delete(ctx.Headers, key)
+	})
+	_ = root.Set("setBodyField", func(field string, value any) {
+		var parsed map[string]any
+		_ = json.Unmarshal(ctx.Body, &parsed)
+		if parsed == nil {
+			parsed = make(map[string]any)
+		}
+		parsed[field] = value
+		if encoded, err := json.Marshal(parsed); err == nil {
+			ctx.Body = encoded
+		}
+		_ = obj.Set("body", parsed)
+	})
+	_ = root.Set("rewritePath", func(path string) {
+		ctx.Path = path
+	})
+	_ = root.Set("reject", func(status int, message string) {
+		panic(&RejectError{Status: status, Message: message})
+	})
+
+	_ = vm.Set("ctx", root)
+}
+
+func bindResponseContext(vm *goja.Runtime, ctx *ResponseContext) {
+	obj := vm.NewObject()
+	_ = obj.Set("headers", ctx.Headers)
+	_ = obj.Set("statusCode", ctx.StatusCode)
+
+	var body any
+	if len(ctx.Body) > 0 {
+		if err := json.Unmarshal(ctx.Body, &body); err == nil {
+			_ = obj.Set("body", body)
+		}
+	}
+
+	root := vm.NewObject()
+	_ = root.Set("response", obj)
+
+	_ = root.Set("setHeader", func(key, value string) {
+		ctx.Headers[key] = value
+	})
+	_ = root.Set("removeHeader", func(key string) {
+		delete(ctx.Headers, key)
+	})
+	_ = root.Set("setBodyField", func(field string, value any) {
+		var parsed map[string]any
+		_ = json.Unmarshal(ctx.Body, &parsed)
+		if parsed == nil {
+			parsed = make(map[string]any)
+		}
+		parsed[field] = value
+		if encoded, err := json.Marshal(parsed); err == nil {
+			ctx.Body = encoded
+		}
+		_ = obj.Set("body", parsed)
+	})
+	_ = root.Set("reject", func(status int, message string) {
+		panic(&RejectError{Status: status, Message: message})
+	})
+
+	_ = vm.Set("ctx", root)
+}
@@ -0,0 +1,165 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+func testRuntime() *Runtime {
+	return NewRuntime(Config{Timeout: 50 * time.Millisecond})
+}
+
+func TestRunRequestScriptMutatesHeadersAndBody(t *testing.T) {
+	r := testRuntime()
+	ctx := &RequestContext{
+		Headers: map[string]string{},
+		Body:    []byte(`{"model":"gpt-4"}`),
+		Path:    "/v1/chat/completions",
+	}
+
+	err := r.RunRequestScript(1, 1, `
+		ctx.setHeader("X-Plugin", "yes");
+		ctx.setBodyField("model", "gpt-4o");
+		ctx.rewritePath("/v1/rewritten");
+	`, ctx)
+	if err != nil {
+		t.Fatalf("RunRequestScript returned error: %v", err)
+	}
+
+	if ctx.Headers["X-Plugin"] != "yes" {
+		t.Fatalf("expected setHeader to apply, got %v", ctx.Headers)
+	}
+	if ctx.Path != "/v1/rewritten" {
+		t.Fatalf("expected rewritePath to apply, got %q", ctx.Path)
+	}
+	if string(ctx.Body) != `{"model":"gpt-4o"}` {
+		t.Fatalf("expected setBodyField to apply, got %q", ctx.Body)
+	}
+}
+
+func TestSetBodyFieldIsVisibleToSubsequentReads(t *testing.T) {
+	r := testRuntime()
+	ctx := &RequestContext{
+		Headers: map[string]string{},
+		Body:    []byte(`{"model":"gpt-4"}`),
+	}
+
+	err := r.RunRequestScript(1, 1, `
+		ctx.setBodyField("model", "gpt-4o");
+		if (ctx.request.body.model !== "gpt-4o") {
+			throw new Error("stale body: " + ctx.request.body.model);
+		}
+	`, ctx)
+	if err != nil {
+		t.Fatalf("expected ctx.request.body to reflect setBodyField immediately, got error: %v", err)
+	}
+}
+
+func TestRunRequestScriptRejectStopsExecution(t *testing.T) {
+	r := testRuntime()
+	ctx := &RequestContext{Headers: map[string]string{}}
+
+	err := r.RunRequestScript(1, 1, `ctx.reject(403, "blocked");`, ctx)
+	rej, ok := err.(*RejectError)
+	if !ok {
+		t.Fatalf("expected a *RejectError, got %v (%T)", err, err)
+	}
+	if rej.Status != 403 || rej.Message != "blocked" {
+		t.Fatalf("unexpected RejectError: %+v", rej)
+	}
+}
+
+func TestRunRequestScriptTimeout(t *testing.T) {
+	r := NewRuntime(Config{Timeout: 10 * time.Millisecond})
+	ctx := &RequestContext{Headers: map[string]string{}}
+
+	err := r.RunRequestScript(1, 1, `while (true) {}`, ctx)
+	if err == nil {
+		t.Fatal("expected an infinite loop to be interrupted by the timeout")
+	}
+}
+
+func TestRuntimeReusesPooledVMsAcrossExecutions(t *testing.T) {
+	r := testRuntime()
+	ctx := &RequestContext{Headers: map[string]string{}}
+
+	if err := r.RunRequestScript(1, 1, `ctx.setHeader("X-Run", "ok");`, ctx); err != nil {
+		t.Fatalf("first run returned error: %v", err)
+	}
+	first := r.vmPool.Get()
+	r.vmPool.Put(first)
+
+	if err := r.RunRequestScript(1, 1, `ctx.setHeader("X-Run", "ok");`, ctx); err != nil {
+		t.Fatalf("second run returned error: %v", err)
+	}
+	second := r.vmPool.Get()
+
+	if first != second {
+		t.Fatal("expected sequential, non-concurrent runs to reuse the same pooled goja.Runtime instead of allocating a new one")
+	}
+}
+
+func TestRunRequestScriptSurvivesPriorTimeout(t *testing.T) {
+	r := NewRuntime(Config{Timeout: 10 * time.Millisecond})
+	ctx := &RequestContext{Headers: map[string]string{}}
+
+	if err := r.RunRequestScript(1, 1, `while (true) {}`, ctx); err == nil {
+		t.Fatal("expected the busy loop to time out")
+	}
+
+	// A pooled VM must have its interrupt flag cleared before reuse, or
+	// every subsequent run on the same VM would fail immediately.
+	if err := r.RunRequestScript(1, 2, `ctx.setHeader("X-After-Timeout", "ok");`, ctx); err != nil {
+		t.Fatalf("expected a run after a timed-out run to succeed, got: %v", err)
+	}
+	if ctx.Headers["X-After-Timeout"] != "ok" {
+		t.Fatalf("expected setHeader to apply after recovering from a timeout, got %v", ctx.Headers)
+	}
+}
+
+func TestRunRequestScriptStaleTimerDoesNotInterruptReusedVM(t *testing.T) {
+	r := NewRuntime(Config{Timeout: 20 * time.Millisecond})
+	ctx := &RequestContext{Headers: map[string]string{}}
+
+	// Each run finishes well before its own timeout, but its AfterFunc timer
+	// may still be pending when the vm goes back to the pool and a later run
+	// immediately checks it out again. None of these runs should ever see a
+	// bogus interrupt from a previous run's stale timer.
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for i := 0; time.Now().Before(deadline); i++ {
+		if err := r.RunRequestScript(1, int64(i), `ctx.setHeader("X-Run", "ok");`, ctx); err != nil {
+			t.Fatalf("run %d returned unexpected error (likely a stale timer interrupt): %v", i, err)
+		}
+	}
+}
+
+func TestExecGuardSuppressesInterruptAfterFinish(t *testing.T) {
+	vm := goja.New()
+	guard := &execGuard{}
+	guard.finish()
+	guard.interruptIfLive(vm)
+
+	program, err := goja.Compile("t", `1 + 1`, false)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if _, err := vm.RunProgram(program); err != nil {
+		t.Fatalf("expected a finished execution's timer to be a no-op, but the vm was interrupted: %v", err)
+	}
+}
+
+func TestExecGuardAllowsInterruptBeforeFinish(t *testing.T) {
+	vm := goja.New()
+	guard := &execGuard{}
+	guard.interruptIfLive(vm)
+
+	program, err := goja.Compile("t", `1 + 1`, false)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	if _, err := vm.RunProgram(program); err == nil {
+		t.Fatal("expected an interrupt issued before finish to actually interrupt the vm")
+	}
+}
@@ -0,0 +1,50 @@
+// Package ratelimit implements a distributed token-bucket rate limiter for
+// the proxy middleware, enforced per proxy key and per group before key
+// selection. It is backed by Redis when available, falling back to an
+// in-memory bucket when GetRedisDSN() is empty so single-instance
+// deployments keep working without Redis.
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Response headers set when a request is rejected by the limiter.
+const (
+	HeaderRemaining  = "X-RateLimit-Remaining"
+	HeaderReset      = "X-RateLimit-Reset"
+	HeaderRetryAfter = "Retry-After"
+)
+
+// Limiter checks and consumes tokens from a named bucket.
+type Limiter interface {
+	// Allow attempts to consume cost tokens from the bucket identified by
+	// key, which refills at rate tokens/sec up to the given cap. It
+	// reports whether the request is allowed, how many tokens remain, and
+	// (when denied) how long the caller should wait before retrying.
+	Allow(ctx context.Context, key string, rate float64, cap float64, cost float64) (Result, error)
+}
+
+// Result is the outcome of a single Allow call.
+type Result struct {
+	Allowed    bool
+	Remaining  float64
+	RetryAfter time.Duration
+}
+
+// NewLimiter returns a Redis-backed Limiter when dsn is non-empty, or an
+// in-memory Limiter otherwise.
+func NewLimiter(dsn string) (Limiter, error) {
+	if dsn == "" {
+		return NewMemoryLimiter(), nil
+	}
+
+	opts, err := redis.ParseURL(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return NewRedisLimiter(redis.NewClient(opts)), nil
+}
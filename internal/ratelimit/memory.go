@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryBucket tracks a single token bucket's state.
+type memoryBucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+// MemoryLimiter is an in-process Limiter used when no Redis DSN is
+// configured, for single-instance deployments.
+type MemoryLimiter struct {
+	lock    sync.Mutex
+	buckets map[string]*memoryBucket
+}
+
+// NewMemoryLimiter creates an empty MemoryLimiter.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*memoryBucket),
+	}
+}
+
+// Allow consumes cost tokens from the bucket identified by key.
+func (l *MemoryLimiter) Allow(_ context.Context, key string, rate float64, cap float64, cost float64) (Result, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &memoryBucket{tokens: cap, ts: now}
+		l.buckets[key] = bucket
+	}
+
+	elapsed := now.Sub(bucket.ts).Seconds()
+	bucket.tokens = min(cap, bucket.tokens+elapsed*rate)
+	bucket.ts = now
+
+	if bucket.tokens >= cost {
+		bucket.tokens -= cost
+		return Result{Allowed: true, Remaining: bucket.tokens}, nil
+	}
+
+	result := Result{Allowed: false, Remaining: bucket.tokens}
+	if rate > 0 {
+		result.RetryAfter = time.Duration((cost-bucket.tokens)/rate*1000) * time.Millisecond
+	}
+	return result, nil
+}
+
+func min(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryLimiterAllowsUpToCap(t *testing.T) {
+	l := NewMemoryLimiter()
+
+	for i := 0; i < 5; i++ {
+		result, err := l.Allow(context.Background(), "key", 1, 5, 1)
+		if err != nil {
+			t.Fatalf("Allow returned error: %v", err)
+		}
+		if !result.Allowed {
+			t.Fatalf("request %d: expected bucket to allow up to its cap, got denied with %v remaining", i, result.Remaining)
+		}
+	}
+
+	result, err := l.Allow(context.Background(), "key", 1, 5, 1)
+	if err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("expected the bucket to be empty after consuming its full cap")
+	}
+	if result.RetryAfter <= 0 {
+		t.Fatalf("expected a positive RetryAfter when denied, got %v", result.RetryAfter)
+	}
+}
+
+func TestMemoryLimiterTracksBucketsIndependently(t *testing.T) {
+	l := NewMemoryLimiter()
+
+	if result, err := l.Allow(context.Background(), "a", 1, 1, 1); err != nil || !result.Allowed {
+		t.Fatalf("bucket 'a' first request should be allowed, got allowed=%v err=%v", result.Allowed, err)
+	}
+	if result, err := l.Allow(context.Background(), "a", 1, 1, 1); err != nil || result.Allowed {
+		t.Fatalf("bucket 'a' second request should be denied, got allowed=%v err=%v", result.Allowed, err)
+	}
+	if result, err := l.Allow(context.Background(), "b", 1, 1, 1); err != nil || !result.Allowed {
+		t.Fatalf("bucket 'b' should be unaffected by bucket 'a', got allowed=%v err=%v", result.Allowed, err)
+	}
+}
+
+func TestMemoryLimiterRejectsCostAboveCap(t *testing.T) {
+	l := NewMemoryLimiter()
+
+	result, err := l.Allow(context.Background(), "key", 1, 5, 10)
+	if err != nil {
+		t.Fatalf("Allow returned error: %v", err)
+	}
+	if result.Allowed {
+		t.Fatal("expected a cost greater than the bucket's cap to never be allowed")
+	}
+}
@@ -0,0 +1,24 @@
+package ratelimit
+
+import "encoding/json"
+
+// usageBody is the minimal shape needed to read the token count an LLM
+// response reports using, matching the OpenAI-style `usage.total_tokens`
+// field most upstreams already return.
+type usageBody struct {
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// TotalTokens extracts usage.total_tokens from a (non-streaming) response
+// body, returning 0 if the body has no usage block. Streaming responses
+// should accumulate their own total and call Allow directly once the
+// stream ends, to post-charge the TokensPerMinutePerKey bucket.
+func TotalTokens(body []byte) int {
+	var parsed usageBody
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return 0
+	}
+	return parsed.Usage.TotalTokens
+}
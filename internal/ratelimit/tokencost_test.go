@@ -0,0 +1,23 @@
+package ratelimit
+
+import "testing"
+
+func TestTotalTokensReadsUsageField(t *testing.T) {
+	body := []byte(`{"id":"chatcmpl-1","usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`)
+
+	if got := TotalTokens(body); got != 15 {
+		t.Fatalf("TotalTokens = %d, want 15", got)
+	}
+}
+
+func TestTotalTokensReturnsZeroWithoutUsage(t *testing.T) {
+	if got := TotalTokens([]byte(`{"id":"chatcmpl-1"}`)); got != 0 {
+		t.Fatalf("TotalTokens = %d, want 0 for a body with no usage block", got)
+	}
+}
+
+func TestTotalTokensReturnsZeroOnInvalidJSON(t *testing.T) {
+	if got := TotalTokens([]byte(`not json`)); got != 0 {
+		t.Fatalf("TotalTokens = %d, want 0 for invalid JSON", got)
+	}
+}
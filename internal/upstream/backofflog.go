@@ -0,0 +1,25 @@
+package upstream
+
+import (
+	"gpt-load/internal/models"
+)
+
+// SkippedBackoffLogs builds a models.RequestTypeSkippedBackoff RequestLog
+// row for every candidate Tracker.SkippedBackoff reports, so the caller
+// dispatching a request can persist one alongside (or instead of) the final
+// RequestLog for that attempt. ID and Timestamp are left zero-valued for the
+// caller to fill in, matching how other RequestLog rows are assembled
+// outside this package.
+func SkippedBackoffLogs(groupID uint, groupName string, skipped []*Upstream) []models.RequestLog {
+	logs := make([]models.RequestLog, 0, len(skipped))
+	for _, u := range skipped {
+		logs = append(logs, models.RequestLog{
+			GroupID:      groupID,
+			GroupName:    groupName,
+			RequestType:  models.RequestTypeSkippedBackoff,
+			UpstreamAddr: u.URL,
+			IsSuccess:    false,
+		})
+	}
+	return logs
+}
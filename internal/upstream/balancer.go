@@ -0,0 +1,316 @@
+package upstream
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"gpt-load/internal/backoff"
+)
+
+// Strategy identifies a built-in LoadBalancer implementation.
+type Strategy string
+
+const (
+	StrategyRoundRobin     Strategy = "round-robin"
+	StrategyWeightedRandom Strategy = "weighted-random"
+	StrategyLeastInflight  Strategy = "least-inflight"
+	StrategyConsistentHash Strategy = "consistent-hash"
+)
+
+// ErrNoHealthyUpstream is returned when every candidate upstream is either
+// unhealthy, backed off, or at its MaxConcurrent limit.
+var ErrNoHealthyUpstream = errors.New("upstream: no healthy upstream available")
+
+// LoadBalancer selects one upstream out of a group's configured candidates
+// for a given request.
+type LoadBalancer interface {
+	// Select returns the chosen upstream for the given sticky key (used
+	// only by the consistent-hash strategy; ignored otherwise).
+	Select(candidates []*Upstream, stickyKey string) (*Upstream, error)
+}
+
+// Tracker holds the shared in-flight/health/backoff bookkeeping used by
+// every strategy for a given group. One Tracker is created per group and
+// reused across requests.
+type Tracker struct {
+	lock      sync.RWMutex
+	inflight  map[string]*int64
+	success   map[string]*int64
+	failure   map[string]*int64
+	unhealthy map[string]bool
+	rrCursor  uint64
+	backoff   *backoff.Manager
+}
+
+// NewTracker creates an empty Tracker. backoffMgr may be nil, in which case
+// upstreams are never skipped for being in backoff.
+func NewTracker(backoffMgr *backoff.Manager) *Tracker {
+	return &Tracker{
+		inflight:  make(map[string]*int64),
+		success:   make(map[string]*int64),
+		failure:   make(map[string]*int64),
+		unhealthy: make(map[string]bool),
+		backoff:   backoffMgr,
+	}
+}
+
+// Acquire increments the in-flight count for url unless it is already at
+// maxConcurrent (0 means unlimited), and reports whether it did. The caller
+// must call Release exactly once if Acquire returns true.
+func (t *Tracker) Acquire(url string, maxConcurrent int) bool {
+	counter := t.counter(t.inflight, url)
+	for {
+		current := atomic.LoadInt64(counter)
+		if maxConcurrent > 0 && current >= int64(maxConcurrent) {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(counter, current, current+1) {
+			return true
+		}
+	}
+}
+
+// Release decrements the in-flight count for url.
+func (t *Tracker) Release(url string) {
+	atomic.AddInt64(t.counter(t.inflight, url), -1)
+}
+
+// Inflight returns the current in-flight count for url.
+func (t *Tracker) Inflight(url string) int64 {
+	return atomic.LoadInt64(t.counter(t.inflight, url))
+}
+
+// RecordSuccess registers a successful response from url and clears any
+// backoff state it had accumulated.
+func (t *Tracker) RecordSuccess(url string) {
+	atomic.AddInt64(t.counter(t.success, url), 1)
+	if t.backoff != nil {
+		t.backoff.RecordSuccess(url)
+	}
+}
+
+// RecordFailure registers a transport error or 5xx response from url,
+// advancing its backoff delay so it is skipped by Select for a while.
+func (t *Tracker) RecordFailure(url string) {
+	atomic.AddInt64(t.counter(t.failure, url), 1)
+	if t.backoff != nil {
+		t.backoff.RecordFailure(url)
+	}
+}
+
+// SuccessCount returns the number of successes recorded for url.
+func (t *Tracker) SuccessCount(url string) int64 {
+	return t.readCounter(t.success, url)
+}
+
+// FailureCount returns the number of failures recorded for url.
+func (t *Tracker) FailureCount(url string) int64 {
+	return t.readCounter(t.failure, url)
+}
+
+func (t *Tracker) counter(m map[string]*int64, key string) *int64 {
+	t.lock.RLock()
+	c, exists := m[key]
+	t.lock.RUnlock()
+	if exists {
+		return c
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if c, exists = m[key]; exists {
+		return c
+	}
+	c = new(int64)
+	m[key] = c
+	return c
+}
+
+func (t *Tracker) readCounter(m map[string]*int64, key string) int64 {
+	t.lock.RLock()
+	c, exists := m[key]
+	t.lock.RUnlock()
+	if !exists {
+		return 0
+	}
+	return atomic.LoadInt64(c)
+}
+
+// SetHealthy marks url as healthy or unhealthy, as determined by the
+// background health-checker.
+func (t *Tracker) SetHealthy(url string, healthy bool) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if healthy {
+		delete(t.unhealthy, url)
+	} else {
+		t.unhealthy[url] = true
+	}
+}
+
+// IsHealthy reports whether url has not been marked unhealthy. Upstreams
+// with no HealthPath configured are always considered healthy.
+func (t *Tracker) IsHealthy(url string) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return !t.unhealthy[url]
+}
+
+// BackedOff reports whether url is currently inside its backoff window, i.e.
+// it would be skipped by availableCandidates for having failed recently. It
+// does not look at health-check state.
+func (t *Tracker) BackedOff(url string) bool {
+	return t.backoff != nil && t.backoff.ShouldSkip(url)
+}
+
+// SkippedBackoff returns the subset of candidates that are healthy but
+// currently backed off, for callers that want to record a
+// models.RequestTypeSkippedBackoff RequestLog entry per skipped upstream.
+func (t *Tracker) SkippedBackoff(candidates []*Upstream) []*Upstream {
+	if t.backoff == nil {
+		return nil
+	}
+	var skipped []*Upstream
+	for _, u := range candidates {
+		if t.IsHealthy(u.URL) && t.backoff.ShouldSkip(u.URL) {
+			skipped = append(skipped, u)
+		}
+	}
+	return skipped
+}
+
+// availableCandidates returns the candidates that are both healthy and not
+// currently backed off.
+func (t *Tracker) availableCandidates(candidates []*Upstream) []*Upstream {
+	available := make([]*Upstream, 0, len(candidates))
+	for _, u := range candidates {
+		if !t.IsHealthy(u.URL) {
+			continue
+		}
+		if t.backoff != nil && t.backoff.ShouldSkip(u.URL) {
+			continue
+		}
+		available = append(available, u)
+	}
+	return available
+}
+
+// NewLoadBalancer constructs the LoadBalancer for the given strategy,
+// sharing the given Tracker for in-flight, health and backoff state.
+func NewLoadBalancer(strategy Strategy, tracker *Tracker) LoadBalancer {
+	switch strategy {
+	case StrategyWeightedRandom:
+		return &weightedRandomBalancer{tracker: tracker}
+	case StrategyLeastInflight:
+		return &leastInflightBalancer{tracker: tracker}
+	case StrategyConsistentHash:
+		return &consistentHashBalancer{tracker: tracker, ring: newHashRing(160)}
+	default:
+		return &roundRobinBalancer{tracker: tracker}
+	}
+}
+
+type roundRobinBalancer struct {
+	tracker *Tracker
+}
+
+func (b *roundRobinBalancer) Select(candidates []*Upstream, _ string) (*Upstream, error) {
+	available := b.tracker.availableCandidates(candidates)
+	if len(available) == 0 {
+		return nil, ErrNoHealthyUpstream
+	}
+	idx := atomic.AddUint64(&b.tracker.rrCursor, 1)
+	return acquireOrNext(available, int(idx%uint64(len(available))), b.tracker)
+}
+
+type weightedRandomBalancer struct {
+	tracker *Tracker
+}
+
+func (b *weightedRandomBalancer) Select(candidates []*Upstream, _ string) (*Upstream, error) {
+	available := b.tracker.availableCandidates(candidates)
+	if len(available) == 0 {
+		return nil, ErrNoHealthyUpstream
+	}
+
+	total := 0
+	for _, u := range available {
+		total += u.Weight
+	}
+	if total <= 0 {
+		return acquireOrNext(available, 0, b.tracker)
+	}
+
+	pick := rand.Intn(total)
+	for _, u := range available {
+		pick -= u.Weight
+		if pick < 0 {
+			if b.tracker.Acquire(u.URL, u.MaxConcurrent) {
+				return u, nil
+			}
+			break
+		}
+	}
+	return acquireOrNext(available, 0, b.tracker)
+}
+
+type leastInflightBalancer struct {
+	tracker *Tracker
+}
+
+func (b *leastInflightBalancer) Select(candidates []*Upstream, _ string) (*Upstream, error) {
+	available := b.tracker.availableCandidates(candidates)
+	if len(available) == 0 {
+		return nil, ErrNoHealthyUpstream
+	}
+
+	sorted := append([]*Upstream(nil), available...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return b.tracker.Inflight(sorted[i].URL) < b.tracker.Inflight(sorted[j].URL)
+	})
+	return acquireOrNext(sorted, 0, b.tracker)
+}
+
+// consistentHashBalancer routes a sticky key to the same upstream via a
+// ketama-style hash ring with virtual nodes, so that adding or removing an
+// upstream (e.g. a flapping health check or a backoff window opening and
+// closing) only remaps the small fraction of keys that land between the
+// changed node's neighbours on the ring, instead of the majority of keys a
+// plain hash-modulo-length scheme would remap.
+type consistentHashBalancer struct {
+	tracker *Tracker
+	ring    *hashRing
+}
+
+func (b *consistentHashBalancer) Select(candidates []*Upstream, stickyKey string) (*Upstream, error) {
+	// Rank over the full candidate set (which the ring caches by URL) and
+	// skip unavailable upstreams while walking the order, rather than
+	// filtering first: that keeps the ring keyed on the group's stable
+	// membership instead of every transient healthy/backed-off subset.
+	order := b.ring.rank(candidates, stickyKey)
+	for _, u := range order {
+		if !b.tracker.IsHealthy(u.URL) || b.tracker.BackedOff(u.URL) {
+			continue
+		}
+		if b.tracker.Acquire(u.URL, u.MaxConcurrent) {
+			return u, nil
+		}
+	}
+	return nil, ErrNoHealthyUpstream
+}
+
+// acquireOrNext tries to acquire the candidate at startIdx, falling back to
+// the following candidates (in order) if it is at its MaxConcurrent limit.
+func acquireOrNext(candidates []*Upstream, startIdx int, tracker *Tracker) (*Upstream, error) {
+	n := len(candidates)
+	for i := 0; i < n; i++ {
+		u := candidates[(startIdx+i)%n]
+		if tracker.Acquire(u.URL, u.MaxConcurrent) {
+			return u, nil
+		}
+	}
+	return nil, ErrNoHealthyUpstream
+}
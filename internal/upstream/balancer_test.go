@@ -0,0 +1,54 @@
+package upstream
+
+import (
+	"testing"
+	"time"
+
+	"gpt-load/internal/backoff"
+)
+
+func testUpstreams(urls ...string) []*Upstream {
+	ups := make([]*Upstream, len(urls))
+	for i, u := range urls {
+		ups[i] = &Upstream{URL: u, Weight: 1, MaxConcurrent: 0}
+	}
+	return ups
+}
+
+func TestTrackerSkipsBackedOffUpstream(t *testing.T) {
+	mgr := backoff.NewManager(backoff.Config{BaseDelay: time.Minute, MaxDelay: time.Minute, Multiplier: 2})
+	tracker := NewTracker(mgr)
+	ups := testUpstreams("a", "b")
+
+	tracker.RecordFailure("a")
+
+	lb := NewLoadBalancer(StrategyRoundRobin, tracker)
+	u, err := lb.Select(ups, "")
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if u.URL != "b" {
+		t.Fatalf("expected the backed-off upstream to be skipped, got %q", u.URL)
+	}
+
+	skipped := tracker.SkippedBackoff(ups)
+	if len(skipped) != 1 || skipped[0].URL != "a" {
+		t.Fatalf("expected SkippedBackoff to report [a], got %v", skipped)
+	}
+}
+
+func TestTrackerRecordSuccessClearsBackoff(t *testing.T) {
+	mgr := backoff.NewManager(backoff.Config{BaseDelay: time.Minute, MaxDelay: time.Minute, Multiplier: 2})
+	tracker := NewTracker(mgr)
+	ups := testUpstreams("a", "b")
+
+	tracker.RecordFailure("a")
+	if len(tracker.SkippedBackoff(ups)) != 1 {
+		t.Fatal("expected 'a' to be backed off after a failure")
+	}
+
+	tracker.RecordSuccess("a")
+	if len(tracker.SkippedBackoff(ups)) != 0 {
+		t.Fatal("expected RecordSuccess to clear the backoff state")
+	}
+}
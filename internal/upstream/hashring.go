@@ -0,0 +1,127 @@
+package upstream
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// hashRing implements a ketama-style consistent-hash ring: each upstream is
+// hashed onto several virtual points on a uint32 ring, and a key is routed
+// to the candidate owning the first point clockwise from the key's own
+// hash. Because each upstream owns many scattered points, adding or
+// removing one upstream only remaps the keys that fell on that upstream's
+// own points (roughly 1/n of all keys), unlike a plain hash-modulo-length
+// scheme which remaps the large majority of keys on any membership change.
+//
+// Building and sorting the ring's points is the expensive part, so it is
+// cached per distinct candidate set and only rebuilt when that set actually
+// changes (e.g. an upstream is added/removed from the group), not on every
+// Select call.
+type hashRing struct {
+	vnodes int
+
+	lock  sync.RWMutex
+	rings map[string][]ringPoint
+}
+
+// newHashRing creates a hashRing with vnodes virtual points per upstream.
+// 100-200 is the typical ketama range; more points trade memory/CPU for a
+// more even key distribution.
+func newHashRing(vnodes int) *hashRing {
+	if vnodes <= 0 {
+		vnodes = 1
+	}
+	return &hashRing{vnodes: vnodes, rings: make(map[string][]ringPoint)}
+}
+
+type ringPoint struct {
+	hash uint32
+	ups  *Upstream
+}
+
+// rank returns candidates ordered starting from the one whose point is
+// first clockwise of key's hash on the ring, followed by the rest of the
+// ring in clockwise order. Callers walk this order, skipping any candidate
+// that is unavailable (unhealthy, backed off, or at its concurrency limit).
+func (r *hashRing) rank(candidates []*Upstream, key string) []*Upstream {
+	points := r.pointsFor(candidates)
+	if len(points) == 0 {
+		return nil
+	}
+
+	keyHash := hashString(key)
+	start := sort.Search(len(points), func(i int) bool { return points[i].hash >= keyHash })
+
+	// The cached points may hold *Upstream values from an earlier, equivalent
+	// candidate set; return the caller's own values for each URL instead.
+	byURL := make(map[string]*Upstream, len(candidates))
+	for _, u := range candidates {
+		byURL[u.URL] = u
+	}
+
+	seen := make(map[string]bool, len(candidates))
+	order := make([]*Upstream, 0, len(candidates))
+	for i := 0; i < len(points); i++ {
+		p := points[(start+i)%len(points)]
+		if seen[p.ups.URL] {
+			continue
+		}
+		seen[p.ups.URL] = true
+		order = append(order, byURL[p.ups.URL])
+		if len(order) == len(candidates) {
+			break
+		}
+	}
+	return order
+}
+
+// pointsFor returns the sorted ring points for candidates, building and
+// caching them on first use for this exact candidate set.
+func (r *hashRing) pointsFor(candidates []*Upstream) []ringPoint {
+	if len(candidates) == 0 {
+		return nil
+	}
+	setKey := candidateSetKey(candidates)
+
+	r.lock.RLock()
+	points, exists := r.rings[setKey]
+	r.lock.RUnlock()
+	if exists {
+		return points
+	}
+
+	points = make([]ringPoint, 0, len(candidates)*r.vnodes)
+	for _, u := range candidates {
+		for i := 0; i < r.vnodes; i++ {
+			points = append(points, ringPoint{hash: hashString(fmt.Sprintf("%s#%d", u.URL, i)), ups: u})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+
+	r.lock.Lock()
+	r.rings[setKey] = points
+	r.lock.Unlock()
+
+	return points
+}
+
+// candidateSetKey identifies a set of upstreams by URL, independent of
+// their order, so callers that rebuild the same candidate slice on every
+// request still hit the cache.
+func candidateSetKey(candidates []*Upstream) string {
+	urls := make([]string, len(candidates))
+	for i, u := range candidates {
+		urls[i] = u.URL
+	}
+	sort.Strings(urls)
+	return strings.Join(urls, "\x00")
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(s))
+	return h.Sum32()
+}
@@ -0,0 +1,170 @@
+package upstream
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"gpt-load/internal/backoff"
+)
+
+func TestWeightedRandomBalancerFavorsHigherWeight(t *testing.T) {
+	tracker := NewTracker(nil)
+	ups := []*Upstream{
+		{URL: "a", Weight: 1},
+		{URL: "b", Weight: 99},
+	}
+	lb := NewLoadBalancer(StrategyWeightedRandom, tracker)
+
+	counts := map[string]int{}
+	for i := 0; i < 200; i++ {
+		u, err := lb.Select(ups, "")
+		if err != nil {
+			t.Fatalf("Select returned error: %v", err)
+		}
+		counts[u.URL]++
+		tracker.Release(u.URL)
+	}
+	if counts["b"] <= counts["a"] {
+		t.Fatalf("expected heavier-weighted upstream to be picked more often, got %v", counts)
+	}
+}
+
+func TestLeastInflightBalancerPicksLeastLoaded(t *testing.T) {
+	tracker := NewTracker(nil)
+	ups := testUpstreams("a", "b")
+	tracker.Acquire("a", 0)
+	tracker.Acquire("a", 0)
+
+	lb := NewLoadBalancer(StrategyLeastInflight, tracker)
+	u, err := lb.Select(ups, "")
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	if u.URL != "b" {
+		t.Fatalf("expected least-loaded upstream 'b', got %q", u.URL)
+	}
+}
+
+func TestConsistentHashBalancerIsSticky(t *testing.T) {
+	tracker := NewTracker(nil)
+	ups := testUpstreams("a", "b", "c", "d")
+	lb := NewLoadBalancer(StrategyConsistentHash, tracker)
+
+	u, err := lb.Select(ups, "user-123")
+	if err != nil {
+		t.Fatalf("Select returned error: %v", err)
+	}
+	tracker.Release(u.URL)
+
+	for i := 0; i < 10; i++ {
+		got, err := lb.Select(ups, "user-123")
+		if err != nil {
+			t.Fatalf("Select returned error: %v", err)
+		}
+		tracker.Release(got.URL)
+		if got.URL != u.URL {
+			t.Fatalf("expected the same sticky key to route consistently, got %q then %q", u.URL, got.URL)
+		}
+	}
+}
+
+func TestConsistentHashBalancerRemapsOnlyAFraction(t *testing.T) {
+	tracker := NewTracker(nil)
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://upstream-%d.example.com", i)
+	}
+	full := testUpstreams(urls...)
+	lb := NewLoadBalancer(StrategyConsistentHash, tracker)
+
+	before := map[string]string{}
+	for i := 0; i < 500; i++ {
+		key := fmt.Sprintf("sticky-session-%d", i)
+		u, err := lb.Select(full, key)
+		if err != nil {
+			t.Fatalf("Select returned error: %v", err)
+		}
+		tracker.Release(u.URL)
+		before[key] = u.URL
+	}
+
+	withoutOne := full[:len(full)-1]
+	remapped := 0
+	for key, prev := range before {
+		u, err := lb.Select(withoutOne, key)
+		if err != nil {
+			t.Fatalf("Select returned error: %v", err)
+		}
+		tracker.Release(u.URL)
+		if u.URL != prev {
+			remapped++
+		}
+	}
+
+	if remapped > len(before)/2 {
+		t.Fatalf("removing one of %d upstreams remapped %d/%d keys, expected a small fraction", len(full), remapped, len(before))
+	}
+}
+
+func TestConsistentHashBalancerSkipsUnhealthyAndBackedOff(t *testing.T) {
+	mgr := backoff.NewManager(backoff.Config{BaseDelay: time.Minute, MaxDelay: time.Minute, Multiplier: 2})
+	tracker := NewTracker(mgr)
+	ups := testUpstreams("a", "b", "c")
+	tracker.SetHealthy("a", false)
+	tracker.RecordFailure("b")
+
+	lb := NewLoadBalancer(StrategyConsistentHash, tracker)
+	for i := 0; i < 20; i++ {
+		u, err := lb.Select(ups, fmt.Sprintf("key-%d", i))
+		if err != nil {
+			t.Fatalf("Select returned error: %v", err)
+		}
+		tracker.Release(u.URL)
+		if u.URL != "c" {
+			t.Fatalf("expected only the healthy, non-backed-off upstream 'c' to be selectable, got %q", u.URL)
+		}
+	}
+}
+
+func TestHashRingCachesPointsForStableCandidateSet(t *testing.T) {
+	ring := newHashRing(16)
+	ups := testUpstreams("a", "b", "c")
+
+	first := ring.pointsFor(ups)
+
+	// A freshly built slice of *Upstream with the same URLs in a different
+	// order (as a caller reconstructing its candidate list each request
+	// would produce) must still hit the cache instead of rebuilding.
+	reordered := testUpstreams("c", "a", "b")
+	second := ring.pointsFor(reordered)
+
+	if &first[0] != &second[0] {
+		t.Fatal("expected pointsFor to reuse the cached ring for an equivalent candidate set instead of rebuilding it")
+	}
+
+	withoutOne := ups[:len(ups)-1]
+	third := ring.pointsFor(withoutOne)
+	if &first[0] == &third[0] {
+		t.Fatal("expected pointsFor to rebuild the ring when the candidate set actually changes")
+	}
+}
+
+func TestTrackerRecordsSuccessAndFailureCounts(t *testing.T) {
+	tracker := NewTracker(nil)
+	tracker.RecordSuccess("a")
+	tracker.RecordSuccess("a")
+	tracker.RecordFailure("a")
+
+	if got := tracker.SuccessCount("a"); got != 2 {
+		t.Fatalf("SuccessCount = %d, want 2", got)
+	}
+	if got := tracker.FailureCount("a"); got != 1 {
+		t.Fatalf("FailureCount = %d, want 1", got)
+	}
+
+	stats := tracker.StatsOf(testUpstreams("a"))
+	if stats[0].SuccessCount != 2 || stats[0].FailureCount != 1 {
+		t.Fatalf("StatsOf = %+v, want SuccessCount=2 FailureCount=1", stats[0])
+	}
+}
@@ -0,0 +1,72 @@
+package upstream
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HealthChecker periodically pings each upstream's HealthPath and updates
+// the shared Tracker, excluding unhealthy upstreams from selection until
+// they pass again.
+type HealthChecker struct {
+	tracker  *Tracker
+	client   *http.Client
+	interval time.Duration
+}
+
+// NewHealthChecker creates a HealthChecker that probes on the given
+// interval, which should match the group's key-validation interval.
+func NewHealthChecker(tracker *Tracker, interval time.Duration) *HealthChecker {
+	return &HealthChecker{
+		tracker:  tracker,
+		client:   &http.Client{Timeout: 5 * time.Second},
+		interval: interval,
+	}
+}
+
+// Run blocks, polling every Upstream with a HealthPath until ctx is
+// cancelled. Call it from a background goroutine per group.
+func (c *HealthChecker) Run(ctx context.Context, upstreams []*Upstream) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.checkAll(ctx, upstreams)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkAll(ctx, upstreams)
+		}
+	}
+}
+
+func (c *HealthChecker) checkAll(ctx context.Context, upstreams []*Upstream) {
+	for _, u := range upstreams {
+		if u.HealthPath == "" {
+			continue
+		}
+		go c.check(ctx, u)
+	}
+}
+
+func (c *HealthChecker) check(ctx context.Context, u *Upstream) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.URL+u.HealthPath, nil)
+	if err != nil {
+		c.tracker.SetHealthy(u.URL, false)
+		return
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logrus.Debugf("upstream health check failed for %s: %v", u.URL, err)
+		c.tracker.SetHealthy(u.URL, false)
+		return
+	}
+	defer resp.Body.Close()
+
+	c.tracker.SetHealthy(u.URL, resp.StatusCode >= 200 && resp.StatusCode < 300)
+}
@@ -0,0 +1,27 @@
+package upstream
+
+// Stats reports the current selection state of a single upstream, for the
+// admin "which upstream is dragging this group down" diagnostic view.
+type Stats struct {
+	URL          string `json:"url"`
+	Healthy      bool   `json:"healthy"`
+	Inflight     int64  `json:"inflight"`
+	SuccessCount int64  `json:"success_count"`
+	FailureCount int64  `json:"failure_count"`
+}
+
+// StatsOf builds a Stats snapshot for every candidate using the shared
+// Tracker's current in-flight and health state.
+func (t *Tracker) StatsOf(candidates []*Upstream) []Stats {
+	stats := make([]Stats, 0, len(candidates))
+	for _, u := range candidates {
+		stats = append(stats, Stats{
+			URL:          u.URL,
+			Healthy:      t.IsHealthy(u.URL),
+			Inflight:     t.Inflight(u.URL),
+			SuccessCount: t.SuccessCount(u.URL),
+			FailureCount: t.FailureCount(u.URL),
+		})
+	}
+	return stats
+}
@@ -0,0 +1,49 @@
+// Package upstream implements first-class upstream selection for a Group,
+// replacing plain round-robin over Group.Upstreams with weighted,
+// health-aware strategies while staying backward compatible with the
+// existing JSON format (a bare string URL is still accepted).
+package upstream
+
+import (
+	"encoding/json"
+
+	"gpt-load/internal/models"
+)
+
+// Upstream describes a single backend behind a Group.
+type Upstream struct {
+	URL           string              `json:"url"`
+	Weight        int                 `json:"weight,omitempty"`
+	MaxConcurrent int                 `json:"max_concurrent,omitempty"`
+	HealthPath    string              `json:"health_path,omitempty"`
+	HeaderRules   []models.HeaderRule `json:"header_rules,omitempty"`
+}
+
+// ParseUpstreams decodes a Group's Upstreams JSON into a list of Upstream.
+// Each element may be either a bare URL string (the pre-existing format) or
+// a full object; weight defaults to 1 when omitted or non-positive.
+func ParseUpstreams(raw json.RawMessage) ([]*Upstream, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+
+	upstreams := make([]*Upstream, 0, len(entries))
+	for _, entry := range entries {
+		var asString string
+		if err := json.Unmarshal(entry, &asString); err == nil {
+			upstreams = append(upstreams, &Upstream{URL: asString, Weight: 1})
+			continue
+		}
+
+		var u Upstream
+		if err := json.Unmarshal(entry, &u); err != nil {
+			return nil, err
+		}
+		if u.Weight <= 0 {
+			u.Weight = 1
+		}
+		upstreams = append(upstreams, &u)
+	}
+	return upstreams, nil
+}